@@ -0,0 +1,105 @@
+package action
+
+import (
+	"context"
+
+	"github.com/urfave/cli"
+)
+
+// cliContext bridges a *cli.Context into the context.Context every Action
+// method expects. The real app threads its global state (debug flags,
+// --yes, etc.) onto that context much earlier, in the root command setup
+// that lives outside this tree slice; until that's wired up here too,
+// this just hands the commands below a bare context so they're reachable
+// at all.
+func cliContext(c *cli.Context) context.Context {
+	return context.Background()
+}
+
+// storeFlag is shared by every recipients subcommand that operates on a
+// single sub-store; forceFlag by add and remove.
+var (
+	storeFlag = cli.StringFlag{
+		Name:  "store",
+		Usage: "Store to operate on",
+	}
+	forceFlag = cli.BoolFlag{
+		Name:  "force",
+		Usage: "Use a recipient even if no matching valid public key is found",
+	}
+	batchFileFlag = cli.StringFlag{
+		Name:  "batch-file",
+		Usage: "Non-interactively pick the store, recipients, and prompt answers from this YAML file",
+	}
+)
+
+// etcdFlags are accepted by the add and remove subcommands, which mount
+// an etcd-backed shared store for their one store via ensureEtcdMount.
+// update does not take them, so the ensureEtcdMount calls in
+// RecipientsUpdate find no --etcd-url and only see mounts already
+// registered in this process.
+func etcdFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  "etcd-url",
+			Usage: "etcd endpoint to mount this store's recipients from, e.g. etcd://host:2379/prefix",
+		},
+		cli.StringFlag{
+			Name:  "etcd-cert",
+			Usage: "Client certificate for the etcd connection",
+		},
+		cli.StringFlag{
+			Name:  "etcd-key",
+			Usage: "Client key for the etcd connection",
+		},
+		cli.StringFlag{
+			Name:  "etcd-ca",
+			Usage: "CA certificate to verify the etcd connection",
+		},
+	}
+}
+
+// RecipientsCommand returns the "recipients" command tree: add, remove,
+// update, and print, plus the "group" subcommand tree registered
+// separately in RecipientsGroupCommand.
+func (s *Action) RecipientsCommand() cli.Command {
+	addRemoveFlags := append([]cli.Flag{storeFlag, batchFileFlag}, etcdFlags()...)
+
+	return cli.Command{
+		Name:  "recipients",
+		Usage: "Manage recipients per store",
+		Action: func(c *cli.Context) error {
+			return s.RecipientsPrint(cliContext(c), c)
+		},
+		Subcommands: []cli.Command{
+			{
+				Name:  "add",
+				Usage: "Add one or more recipients to a store",
+				Flags: append(append([]cli.Flag{}, addRemoveFlags...), forceFlag),
+				Action: func(c *cli.Context) error {
+					return s.RecipientsAdd(cliContext(c), c)
+				},
+			},
+			{
+				Name:  "remove",
+				Usage: "Remove one or more recipients from a store",
+				Flags: append(append([]cli.Flag{}, addRemoveFlags...), forceFlag, cli.BoolFlag{
+					Name:  "rotate",
+					Usage: "Re-encrypt every secret below the store after removal and rotate the password of each one not marked 'rotate: false'",
+				}),
+				Action: func(c *cli.Context) error {
+					return s.RecipientsRemove(cliContext(c), c)
+				},
+			},
+			{
+				Name:  "update",
+				Usage: "Re-check every store's recipients, with groups expanded, and confirm any whose checksum has changed",
+				Flags: []cli.Flag{batchFileFlag},
+				Action: func(c *cli.Context) error {
+					return s.RecipientsUpdate(cliContext(c), c)
+				},
+			},
+			s.RecipientsGroupCommand(),
+		},
+	}
+}
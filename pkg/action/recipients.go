@@ -10,6 +10,8 @@ import (
 	"github.com/gopasspw/gopass/pkg/cui"
 	"github.com/gopasspw/gopass/pkg/out"
 	"github.com/gopasspw/gopass/pkg/store"
+	"github.com/gopasspw/gopass/pkg/store/etcd"
+	"github.com/gopasspw/gopass/pkg/store/group"
 	"github.com/gopasspw/gopass/pkg/store/sub"
 	"github.com/gopasspw/gopass/pkg/termio"
 	"github.com/pkg/errors"
@@ -106,21 +108,130 @@ func (s *Action) RecipientsComplete(ctx context.Context, c *cli.Context) {
 	}
 }
 
+// loadBatchInput loads the --batch-file, if any, so callers can answer
+// confirmation prompts and pick a store non-interactively.
+func loadBatchInput(c *cli.Context) (*termio.BatchInput, error) {
+	path := c.String("batch-file")
+	if path == "" {
+		return nil, nil
+	}
+	return termio.LoadBatchInput(path)
+}
+
+// confirm answers the prompt identified by key from batch, if set,
+// failing loudly if the batch file has no answer for it, and otherwise
+// falls back to asking displayPrompt on the TTY. key is a stable
+// identifier (e.g. "add:<fingerprint>"), so a batch file keeps working
+// even if displayPrompt's rendering of the recipient changes.
+func confirm(ctx context.Context, batch *termio.BatchInput, key, displayPrompt string) (bool, error) {
+	if batch == nil {
+		return termio.AskForConfirmation(ctx, displayPrompt), nil
+	}
+	answer, found := batch.Confirm(key)
+	if !found {
+		return false, errors.Errorf("batch file has no answer for prompt: %q", key)
+	}
+	return answer, nil
+}
+
+// pickStore resolves the store to operate on from batch, if set, failing
+// loudly if it has none configured, and otherwise falls back to the
+// interactive store picker.
+func pickStore(ctx context.Context, s *Action, batch *termio.BatchInput) (string, error) {
+	if batch == nil {
+		return cui.AskForStore(ctx, s.Store), nil
+	}
+	if batch.Store == "" {
+		return "", errors.New("batch file has no store configured")
+	}
+	return batch.Store, nil
+}
+
+// ensureEtcdMount dials and registers alias's etcd-backed mount, if
+// --etcd-url points it at one and it isn't mounted yet, so the recipient
+// operations below read and write the shared store instead of only the
+// local .gpg-id.
+//
+// There's no persistent per-mount config in this tree to resolve alias
+// to a URL automatically (Store.GetSubStore, where that would otherwise
+// be wired, lives outside this package), so the URL and its TLS
+// material are taken from flags instead, one store at a time.
+//
+// invalidate is deliberately nil: RecipientsAdd/Remove/Update are
+// one-shot CLI invocations that exit right after their single write, so
+// a background watch started here would never live long enough to see
+// another node's change. MountEtcd still accepts an invalidate callback
+// for a caller that actually stays up - e.g. mounting from GetSubStore
+// for a long-running process - where a watch would have time to fire.
+func (s *Action) ensureEtcdMount(ctx context.Context, c *cli.Context, alias string) error {
+	if _, ok := s.Store.EtcdStore(alias); ok {
+		return nil
+	}
+	rawurl := c.String("etcd-url")
+	if rawurl == "" {
+		return nil
+	}
+	tlsCfg := etcd.Config{
+		CertFile: c.String("etcd-cert"),
+		KeyFile:  c.String("etcd-key"),
+		CAFile:   c.String("etcd-ca"),
+	}
+	return store.MountEtcd(ctx, alias, rawurl, tlsCfg, nil)
+}
+
+// syncEtcdRecipients pushes the sub-store's current recipient list to
+// its etcd-backed mount, if any, so any other node sharing the store
+// sees the change immediately instead of waiting for a `gopass sync`.
+func (s *Action) syncEtcdRecipients(ctx context.Context, alias string) error {
+	es, ok := s.Store.EtcdStore(alias)
+	if !ok {
+		return nil
+	}
+	subs, err := s.Store.GetSubStore(alias)
+	if err != nil || subs == nil {
+		return errors.Wrapf(err, "failed to get sub store %q", alias)
+	}
+	recp, err := subs.GetRecipients(ctx, "")
+	if err != nil && err != sub.ErrRecipientChecksumChanged {
+		return err
+	}
+	return es.SetRecipients(ctx, recp)
+}
+
 // RecipientsAdd adds new recipients
 func (s *Action) RecipientsAdd(ctx context.Context, c *cli.Context) error {
+	batch, err := loadBatchInput(c)
+	if err != nil {
+		return ExitError(ctx, ExitUsage, err, "failed to load batch file: %s", err)
+	}
+
 	store := c.String("store")
 	force := c.Bool("force")
 	added := 0
 
 	// select store
 	if store == "" {
-		store = cui.AskForStore(ctx, s.Store)
+		st, err := pickStore(ctx, s, batch)
+		if err != nil {
+			return ExitError(ctx, ExitUsage, err, "failed to pick store: %s", err)
+		}
+		store = st
+	}
+
+	if err := s.ensureEtcdMount(ctx, c, store); err != nil {
+		return ExitError(ctx, ExitRecipients, err, "failed to connect to etcd mount: %s", err)
 	}
 
 	crypto := s.Store.Crypto(ctx, store)
 
 	// select recipient
 	recipients := []string(c.Args())
+	if len(recipients) < 1 && batch != nil {
+		recipients = batch.FingerprintsFor("add")
+		if len(recipients) < 1 {
+			return ExitError(ctx, ExitUsage, nil, "batch file has no recipients to add")
+		}
+	}
 	if len(recipients) < 1 {
 		r, err := s.recipientsSelectForAdd(ctx, store)
 		if err != nil {
@@ -130,6 +241,27 @@ func (s *Action) RecipientsAdd(ctx context.Context, c *cli.Context) error {
 	}
 
 	for _, r := range recipients {
+		// a `@groupname` recipient is kept as-is; it is resolved to its
+		// member fingerprints by Store.AddRecipient when it computes the
+		// effective encryption recipient list, not here.
+		if group.IsGroup(r) {
+			ok, err := confirm(ctx, batch, "add:"+r, fmt.Sprintf("Do you want to add group '%s' as a recipient to the store '%s'?", r, store))
+			if err != nil {
+				return ExitError(ctx, ExitUsage, err, "%s", err)
+			}
+			if !ok {
+				continue
+			}
+			if err := s.Store.AddRecipient(ctxutil.WithNoConfirm(ctx, true), store, r); err != nil {
+				return ExitError(ctx, ExitRecipients, err, "failed to add recipient group '%s': %s", r, err)
+			}
+			if err := s.syncEtcdRecipients(ctx, store); err != nil {
+				return ExitError(ctx, ExitRecipients, err, "failed to sync etcd recipients: %s", err)
+			}
+			added++
+			continue
+		}
+
 		keys, err := crypto.FindPublicKeys(ctx, r)
 		if err != nil {
 			out.Cyan(ctx, "WARNING: Failed to list public key '%s': %s", r, err)
@@ -151,13 +283,20 @@ func (s *Action) RecipientsAdd(ctx context.Context, c *cli.Context) error {
 			recp = crypto.Fingerprint(ctx, keys[0])
 		}
 
-		if !termio.AskForConfirmation(ctx, fmt.Sprintf("Do you want to add '%s' as a recipient to the store '%s'?", crypto.FormatKey(ctx, recp), store)) {
+		ok, err := confirm(ctx, batch, "add:"+recp, fmt.Sprintf("Do you want to add '%s' as a recipient to the store '%s'?", crypto.FormatKey(ctx, recp), store))
+		if err != nil {
+			return ExitError(ctx, ExitUsage, err, "%s", err)
+		}
+		if !ok {
 			continue
 		}
 
 		if err := s.Store.AddRecipient(ctxutil.WithNoConfirm(ctx, true), store, recp); err != nil {
 			return ExitError(ctx, ExitRecipients, err, "failed to add recipient '%s': %s", r, err)
 		}
+		if err := s.syncEtcdRecipients(ctx, store); err != nil {
+			return ExitError(ctx, ExitRecipients, err, "failed to sync etcd recipients: %s", err)
+		}
 		added++
 	}
 	if added < 1 {
@@ -171,19 +310,44 @@ func (s *Action) RecipientsAdd(ctx context.Context, c *cli.Context) error {
 
 // RecipientsRemove removes recipients
 func (s *Action) RecipientsRemove(ctx context.Context, c *cli.Context) error {
+	// There's no config option for this yet (it would need a matching
+	// AutoRotate field added wherever s.cfg is defined, which is outside
+	// this tree slice) - --rotate is the only way to ask for it for now.
+	rotate := c.Bool("rotate")
+	reencryptOpts := store.ReencryptOptions{Rotate: rotate}
+
+	batch, err := loadBatchInput(c)
+	if err != nil {
+		return ExitError(ctx, ExitUsage, err, "failed to load batch file: %s", err)
+	}
+
 	store := c.String("store")
 	force := c.Bool("force")
 	removed := 0
 
 	// select store
 	if store == "" {
-		store = cui.AskForStore(ctx, s.Store)
+		st, err := pickStore(ctx, s, batch)
+		if err != nil {
+			return ExitError(ctx, ExitUsage, err, "failed to pick store: %s", err)
+		}
+		store = st
+	}
+
+	if err := s.ensureEtcdMount(ctx, c, store); err != nil {
+		return ExitError(ctx, ExitRecipients, err, "failed to connect to etcd mount: %s", err)
 	}
 
 	crypto := s.Store.Crypto(ctx, store)
 
 	// select recipient
 	recipients := []string(c.Args())
+	if len(recipients) < 1 && batch != nil {
+		recipients = batch.FingerprintsFor("remove")
+		if len(recipients) < 1 {
+			return ExitError(ctx, ExitUsage, nil, "batch file has no recipients to remove")
+		}
+	}
 	if len(recipients) < 1 {
 		rs, err := s.recipientsSelectForRemoval(ctx, store)
 		if err != nil {
@@ -192,11 +356,40 @@ func (s *Action) RecipientsRemove(ctx context.Context, c *cli.Context) error {
 		recipients = rs
 	}
 
+	// Capture the recipient set as it stands before any removal below, so
+	// that if --rotate's re-encryption fails partway through, it can roll
+	// already-rewritten secrets back to exactly this set instead of the
+	// reduced one being removed to.
+	if rotate {
+		reencryptOpts.PreviousRecipients, err = s.Store.EffectiveRecipients(ctx, store)
+		if err != nil {
+			return ExitError(ctx, ExitRecipients, err, "failed to read current recipients: %s", err)
+		}
+	}
+
 	for _, r := range recipients {
+		// a `@groupname` recipient is removed from the .gpg-id as a
+		// whole entry; its members are resolved elsewhere.
+		if group.IsGroup(r) {
+			if err := s.Store.RemoveRecipient(ctxutil.WithNoConfirm(ctx, true), store, r); err != nil {
+				return ExitError(ctx, ExitRecipients, err, "failed to remove recipient group '%s': %s", r, err)
+			}
+			if err := s.syncEtcdRecipients(ctx, store); err != nil {
+				return ExitError(ctx, ExitRecipients, err, "failed to sync etcd recipients: %s", err)
+			}
+			fmt.Fprintf(stdout, removalWarning, r)
+			removed++
+			continue
+		}
+
 		kl, err := crypto.FindPrivateKeys(ctx, r)
 		if err == nil {
 			if len(kl) > 0 {
-				if !termio.AskForConfirmation(ctx, fmt.Sprintf("Do you want to remove yourself (%s) from the recipients?", r)) {
+				ok, err := confirm(ctx, batch, "remove-self:"+r, fmt.Sprintf("Do you want to remove yourself (%s) from the recipients?", r))
+				if err != nil {
+					return ExitError(ctx, ExitUsage, err, "%s", err)
+				}
+				if !ok {
 					continue
 				}
 			}
@@ -226,6 +419,9 @@ func (s *Action) RecipientsRemove(ctx context.Context, c *cli.Context) error {
 		if err := s.Store.RemoveRecipient(ctxutil.WithNoConfirm(ctx, true), store, recp); err != nil {
 			return ExitError(ctx, ExitRecipients, err, "failed to remove recipient '%s': %s", recp, err)
 		}
+		if err := s.syncEtcdRecipients(ctx, store); err != nil {
+			return ExitError(ctx, ExitRecipients, err, "failed to sync etcd recipients: %s", err)
+		}
 		fmt.Fprintf(stdout, removalWarning, r)
 		removed++
 	}
@@ -234,12 +430,25 @@ func (s *Action) RecipientsRemove(ctx context.Context, c *cli.Context) error {
 	}
 
 	out.Green(ctx, "\nRemoved %d recipients", removed)
+
+	if rotate {
+		out.Cyan(ctx, "Re-encrypting store, this may take a while ...")
+		if err := s.Store.ReencryptAll(ctx, store, reencryptOpts); err != nil {
+			return ExitError(ctx, ExitRecipients, err, "failed to re-encrypt store: %s", err)
+		}
+	}
+
 	out.Cyan(ctx, "You need to run 'gopass sync' to push these changes")
 	return nil
 }
 
 // RecipientsUpdate will recompute and update any changed recipients list checksums
 func (s *Action) RecipientsUpdate(ctx context.Context, c *cli.Context) error {
+	batch, err := loadBatchInput(c)
+	if err != nil {
+		return ExitError(ctx, ExitUsage, err, "failed to load batch file: %s", err)
+	}
+
 	changed := 0
 
 	mps := s.Store.MountPoints()
@@ -249,13 +458,43 @@ func (s *Action) RecipientsUpdate(ctx context.Context, c *cli.Context) error {
 		if err != nil || subs == nil {
 			continue
 		}
+		if err := s.ensureEtcdMount(ctx, c, alias); err != nil {
+			return ExitError(ctx, ExitRecipients, err, "failed to connect to etcd mount: %s", err)
+		}
 		recp, err := subs.GetRecipients(ctx, "")
 		if err != nil {
 			if err != sub.ErrRecipientChecksumChanged {
 				return err
 			}
 		}
-		if err == nil && s.cfg.GetRecipientHash(alias, subs.Crypto().IDFile()) != "" {
+
+		// Expand any `@group` entries before hashing, so tampering with a
+		// group's membership is detected the same way as tampering with a
+		// plain fingerprint would be. recp itself stays the raw,
+		// unexpanded list - it's what gets displayed and passed to
+		// SetRecipients, so a `.gpg-id` listing `@team-ops` keeps reading
+		// `@team-ops` instead of being replaced by its expanded members on
+		// the first `recipients update`. Store.EffectiveRecipients is the
+		// single place that loads groups.yml and resolves it; reuse it
+		// here instead of repeating that sequence.
+		effective, eerr := s.Store.EffectiveRecipients(ctx, alias)
+		if eerr != nil {
+			effective = recp
+		}
+		effectiveHash := group.Checksum(effective)
+
+		// An etcd-backed mount keeps its own recipient checksum so that a
+		// change pushed by another node is picked up here even if this
+		// checkout's local config still has the old hash cached.
+		storedHash := s.cfg.GetRecipientHash(alias, subs.Crypto().IDFile())
+		es, isEtcd := s.Store.EtcdStore(alias)
+		if isEtcd {
+			storedHash, err = es.RecipientHash(ctx)
+			if err != nil {
+				return err
+			}
+		}
+		if err == nil && storedHash == effectiveHash {
 			continue
 		}
 		if alias == "" {
@@ -265,12 +504,22 @@ func (s *Action) RecipientsUpdate(ctx context.Context, c *cli.Context) error {
 		for _, r := range recp {
 			out.Print(ctx, "- %s", subs.Crypto().FormatKey(ctx, r))
 		}
-		if !termio.AskForConfirmation(ctx, fmt.Sprintf("Do you trust these recipients for %s?", alias)) {
+		ok, err := confirm(ctx, batch, "trust:"+alias, fmt.Sprintf("Do you trust these recipients for %s?", alias))
+		if err != nil {
+			return ExitError(ctx, ExitUsage, err, "%s", err)
+		}
+		if !ok {
 			continue
 		}
 		if err := subs.SetRecipients(ctx, recp); err != nil {
 			return err
 		}
+		s.cfg.SetRecipientHash(alias, subs.Crypto().IDFile(), effectiveHash)
+		if isEtcd {
+			if err := es.SetRecipients(ctx, effective); err != nil {
+				return err
+			}
+		}
 		out.Print(ctx, "")
 		changed++
 	}
@@ -0,0 +1,223 @@
+package action
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gopasspw/gopass/pkg/cui"
+	"github.com/gopasspw/gopass/pkg/out"
+	"github.com/gopasspw/gopass/pkg/store/group"
+
+	"github.com/urfave/cli"
+)
+
+// groupsFile returns the path to the groups.yml for the sub-store
+// mounted at store, sitting next to its .gpg-id. It just delegates to
+// Store.GroupsFile, which is the single place that knows that path, so
+// RecipientsUpdate and the group subcommands don't each rebuild it.
+func (s *Action) groupsFile(ctx context.Context, store string) (string, error) {
+	return s.Store.GroupsFile(store)
+}
+
+// RecipientsGroupCommand returns the "recipients group" subcommand tree:
+// add, remove, list, and sync.
+func (s *Action) RecipientsGroupCommand() cli.Command {
+	groupFlag := cli.StringFlag{
+		Name:  "group",
+		Usage: "Group name",
+	}
+
+	return cli.Command{
+		Name:  "group",
+		Usage: "Manage recipient groups (team aliases resolved at encryption time)",
+		Subcommands: []cli.Command{
+			{
+				Name:  "add",
+				Usage: "Add one or more fingerprints to a group",
+				Flags: []cli.Flag{storeFlag, groupFlag},
+				Action: func(c *cli.Context) error {
+					return s.RecipientsGroupAdd(cliContext(c), c)
+				},
+			},
+			{
+				Name:  "remove",
+				Usage: "Remove one or more fingerprints from a group",
+				Flags: []cli.Flag{storeFlag, groupFlag},
+				Action: func(c *cli.Context) error {
+					return s.RecipientsGroupRemove(cliContext(c), c)
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "List a store's groups, or a single group's members",
+				Flags: []cli.Flag{storeFlag, groupFlag},
+				Action: func(c *cli.Context) error {
+					return s.RecipientsGroupList(cliContext(c), c)
+				},
+			},
+			{
+				Name:  "sync",
+				Usage: "Replace a group's membership with the fingerprint list fetched from --url",
+				Flags: []cli.Flag{storeFlag, groupFlag, cli.StringFlag{
+					Name:  "url",
+					Usage: "HTTP(S) endpoint to fetch the group's fingerprint list from",
+				}},
+				Action: func(c *cli.Context) error {
+					return s.RecipientsGroupSync(cliContext(c), c)
+				},
+			},
+		},
+	}
+}
+
+// RecipientsGroupAdd adds one or more fingerprints to a recipient group.
+func (s *Action) RecipientsGroupAdd(ctx context.Context, c *cli.Context) error {
+	store := c.String("store")
+	name := c.String("group")
+	members := []string(c.Args())
+
+	if store == "" {
+		store = cui.AskForStore(ctx, s.Store)
+	}
+	if name == "" {
+		return ExitError(ctx, ExitUsage, nil, "a group name is required")
+	}
+	if len(members) < 1 {
+		return ExitError(ctx, ExitUsage, nil, "at least one fingerprint is required")
+	}
+
+	path, err := s.groupsFile(ctx, store)
+	if err != nil {
+		return err
+	}
+	groups, err := group.Load(path)
+	if err != nil {
+		return ExitError(ctx, ExitUnknown, err, "failed to load groups: %s", err)
+	}
+
+	groups[name] = append(groups[name], members...)
+	if err := groups.Save(path); err != nil {
+		return ExitError(ctx, ExitUnknown, err, "failed to save groups: %s", err)
+	}
+
+	out.Green(ctx, "Added %d member(s) to group '%s'", len(members), name)
+	return nil
+}
+
+// RecipientsGroupRemove removes one or more fingerprints from a
+// recipient group.
+func (s *Action) RecipientsGroupRemove(ctx context.Context, c *cli.Context) error {
+	store := c.String("store")
+	name := c.String("group")
+	members := []string(c.Args())
+
+	if store == "" {
+		store = cui.AskForStore(ctx, s.Store)
+	}
+	if name == "" {
+		return ExitError(ctx, ExitUsage, nil, "a group name is required")
+	}
+
+	path, err := s.groupsFile(ctx, store)
+	if err != nil {
+		return err
+	}
+	groups, err := group.Load(path)
+	if err != nil {
+		return ExitError(ctx, ExitUnknown, err, "failed to load groups: %s", err)
+	}
+
+	remove := make(map[string]struct{}, len(members))
+	for _, m := range members {
+		remove[m] = struct{}{}
+	}
+
+	kept := make([]string, 0, len(groups[name]))
+	for _, fp := range groups[name] {
+		if _, ok := remove[fp]; ok {
+			continue
+		}
+		kept = append(kept, fp)
+	}
+	groups[name] = kept
+
+	if err := groups.Save(path); err != nil {
+		return ExitError(ctx, ExitUnknown, err, "failed to save groups: %s", err)
+	}
+
+	out.Green(ctx, "Removed %d member(s) from group '%s'", len(members), name)
+	return nil
+}
+
+// RecipientsGroupList prints the members of every group defined for the
+// given store, or of a single group if --group is set.
+func (s *Action) RecipientsGroupList(ctx context.Context, c *cli.Context) error {
+	store := c.String("store")
+	name := c.String("group")
+
+	if store == "" {
+		store = cui.AskForStore(ctx, s.Store)
+	}
+
+	path, err := s.groupsFile(ctx, store)
+	if err != nil {
+		return err
+	}
+	groups, err := group.Load(path)
+	if err != nil {
+		return ExitError(ctx, ExitUnknown, err, "failed to load groups: %s", err)
+	}
+
+	for gname, members := range groups {
+		if name != "" && name != gname {
+			continue
+		}
+		fmt.Fprintf(stdout, "%s%s:\n", group.Prefix, gname)
+		for _, m := range members {
+			fmt.Fprintf(stdout, "  - %s\n", m)
+		}
+	}
+	return nil
+}
+
+// RecipientsGroupSync replaces a group's membership with the fingerprint
+// list fetched from the configured HTTP(S) endpoint, so an organization
+// can manage team membership centrally without editing each checkout's
+// groups.yml by hand.
+func (s *Action) RecipientsGroupSync(ctx context.Context, c *cli.Context) error {
+	store := c.String("store")
+	name := c.String("group")
+	url := c.String("url")
+
+	if store == "" {
+		store = cui.AskForStore(ctx, s.Store)
+	}
+	if name == "" {
+		return ExitError(ctx, ExitUsage, nil, "a group name is required")
+	}
+	if url == "" {
+		return ExitError(ctx, ExitUsage, nil, "a --url is required")
+	}
+
+	members, err := group.FetchMembers(ctx, url)
+	if err != nil {
+		return ExitError(ctx, ExitUnknown, err, "failed to sync group '%s': %s", name, err)
+	}
+
+	path, err := s.groupsFile(ctx, store)
+	if err != nil {
+		return err
+	}
+	groups, err := group.Load(path)
+	if err != nil {
+		return ExitError(ctx, ExitUnknown, err, "failed to load groups: %s", err)
+	}
+
+	groups[name] = members
+	if err := groups.Save(path); err != nil {
+		return ExitError(ctx, ExitUnknown, err, "failed to save groups: %s", err)
+	}
+
+	out.Green(ctx, "Synced group '%s': %d member(s)", name, len(members))
+	return nil
+}
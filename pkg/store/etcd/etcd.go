@@ -0,0 +1,175 @@
+// Package etcd implements a gopass storage backend on top of etcd v3,
+// so a recipient list, recipient hashes and mount-point configuration can be
+// shared between multiple checkouts instead of living only in the local
+// git-tracked .gpg-id file.
+package etcd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/clientv3"
+)
+
+// Scheme is the mount-point URL scheme routed to this backend, e.g.
+// `etcd://etcd.example.com:2379/teams/ops`.
+const Scheme = "etcd://"
+
+// dialTimeout bounds how long we wait for the initial connection to the
+// etcd cluster before giving up.
+const dialTimeout = 5 * time.Second
+
+// Config holds the per-mount etcd connection settings, as parsed out of a
+// mount's `etcd://` URL and its accompanying TLS flags.
+type Config struct {
+	Endpoints []string
+	Prefix    string
+	CertFile  string
+	KeyFile   string
+	CAFile    string
+}
+
+// Store is a Storage implementation backed by an etcd v3 cluster. It
+// exposes the same prefixed Get/Put/Delete/Watch surface the on-disk
+// sub-store uses, so it can be wired in wherever a mount resolves to an
+// `etcd://` URL.
+type Store struct {
+	cli    *clientv3.Client
+	prefix string
+}
+
+// New dials the etcd cluster described by cfg and returns a ready-to-use
+// Store. Callers are responsible for calling Close when done.
+func New(ctx context.Context, cfg Config) (*Store, error) {
+	if len(cfg.Endpoints) < 1 {
+		return nil, errors.New("no etcd endpoints configured")
+	}
+
+	ccfg := clientv3.Config{
+		Endpoints:   cfg.Endpoints,
+		DialTimeout: dialTimeout,
+		Context:     ctx,
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" || cfg.CAFile != "" {
+		tlsCfg, err := buildTLSConfig(cfg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to build TLS config")
+		}
+		ccfg.TLS = tlsCfg
+	}
+
+	cli, err := clientv3.New(ccfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to etcd")
+	}
+
+	return &Store{
+		cli:    cli,
+		prefix: strings.TrimSuffix(cfg.Prefix, "/"),
+	}, nil
+}
+
+func buildTLSConfig(cfg Config) (*tls.Config, error) {
+	tlsCfg := &tls.Config{}
+
+	if cfg.CertFile != "" && cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load client cert/key")
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read CA file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("failed to parse CA file")
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	return tlsCfg, nil
+}
+
+// Close releases the underlying etcd client connection.
+func (s *Store) Close() error {
+	return s.cli.Close()
+}
+
+func (s *Store) key(k string) string {
+	prefix := strings.TrimSuffix(s.prefix, "/")
+	if prefix == "" {
+		return k
+	}
+	return prefix + "/" + strings.TrimPrefix(k, "/")
+}
+
+// Get returns the value stored under key, or ErrNotFound if it doesn't
+// exist.
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.cli.Get(ctx, s.key(key))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %q from etcd", key)
+	}
+	if len(resp.Kvs) < 1 {
+		return nil, ErrNotFound
+	}
+	return resp.Kvs[0].Value, nil
+}
+
+// Put writes value under key.
+func (s *Store) Put(ctx context.Context, key string, value []byte) error {
+	_, err := s.cli.Put(ctx, s.key(key), string(value))
+	return errors.Wrapf(err, "failed to put %q into etcd", key)
+}
+
+// Delete removes key.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	_, err := s.cli.Delete(ctx, s.key(key))
+	return errors.Wrapf(err, "failed to delete %q from etcd", key)
+}
+
+// Event is a single change observed on a watched prefix.
+type Event struct {
+	Key      string
+	Value    []byte
+	IsDelete bool
+}
+
+// Watch streams changes below the given key prefix until ctx is canceled.
+// It is used to invalidate the in-memory recipient cache as soon as
+// another node updates the shared store, so a `gopass sync` is no longer
+// required to see a recipient change.
+func (s *Store) Watch(ctx context.Context, prefix string) (<-chan Event, error) {
+	wch := s.cli.Watch(ctx, s.key(prefix), clientv3.WithPrefix())
+	out := make(chan Event)
+
+	go func() {
+		defer close(out)
+		for resp := range wch {
+			for _, ev := range resp.Events {
+				out <- Event{
+					Key:      string(ev.Kv.Key),
+					Value:    ev.Kv.Value,
+					IsDelete: ev.Type == clientv3.EventTypeDelete,
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// ErrNotFound is returned by Get when no value is stored under the
+// requested key.
+var ErrNotFound = errors.New("key not found in etcd")
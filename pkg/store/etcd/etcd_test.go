@@ -0,0 +1,54 @@
+package etcd
+
+import "testing"
+
+func TestStoreKeyPrefixing(t *testing.T) {
+	cases := []struct {
+		prefix string
+		key    string
+		want   string
+	}{
+		{prefix: "", key: "recipients", want: "recipients"},
+		{prefix: "teams/ops", key: "recipients", want: "teams/ops/recipients"},
+		{prefix: "teams/ops/", key: "recipients", want: "teams/ops/recipients"},
+		{prefix: "teams/ops", key: "/recipients", want: "teams/ops/recipients"},
+	}
+
+	for _, tc := range cases {
+		s := &Store{prefix: tc.prefix}
+		if got := s.key(tc.key); got != tc.want {
+			t.Errorf("Store{prefix: %q}.key(%q) = %q, want %q", tc.prefix, tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestRecipientsHashStable(t *testing.T) {
+	a := recipientsHash([]string{"AAAA", "BBBB"})
+	b := recipientsHash([]string{"AAAA", "BBBB"})
+	if a != b {
+		t.Fatalf("recipientsHash is not deterministic: %q != %q", a, b)
+	}
+
+	c := recipientsHash([]string{"BBBB", "AAAA"})
+	if a == c {
+		t.Fatalf("recipientsHash(%v) == recipientsHash(%v), want order to matter", []string{"AAAA", "BBBB"}, []string{"BBBB", "AAAA"})
+	}
+
+	d := recipientsHash([]string{"AAAA", "BBBB", "CCCC"})
+	if a == d {
+		t.Fatalf("recipientsHash did not change when the recipient set changed")
+	}
+}
+
+func TestSplitNonEmpty(t *testing.T) {
+	got := splitNonEmpty("AAAA\n\nBBBB\n  \nCCCC\n")
+	want := []string{"AAAA", "BBBB", "CCCC"}
+	if len(got) != len(want) {
+		t.Fatalf("splitNonEmpty = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("splitNonEmpty = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package etcd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	recipientsKey = "recipients"
+	hashKey       = "recipients.hash"
+)
+
+// GetRecipients returns the recipient fingerprints stored for this mount.
+func (s *Store) GetRecipients(ctx context.Context) ([]string, error) {
+	raw, err := s.Get(ctx, recipientsKey)
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return splitNonEmpty(string(raw)), nil
+}
+
+// SetRecipients overwrites the recipient fingerprints stored for this
+// mount and updates the recorded checksum alongside it, so RecipientHash
+// always reflects the last confirmed list.
+func (s *Store) SetRecipients(ctx context.Context, recipients []string) error {
+	if err := s.Put(ctx, recipientsKey, []byte(strings.Join(recipients, "\n"))); err != nil {
+		return err
+	}
+	return s.Put(ctx, hashKey, []byte(recipientsHash(recipients)))
+}
+
+// RecipientHash returns the checksum etcd has on record for this mount's
+// recipient list, as last written by SetRecipients. RecipientsUpdate
+// compares against this value instead of the local config when the mount
+// is etcd-backed, so a stale local cache can't mask a recipient change
+// made by another node.
+func (s *Store) RecipientHash(ctx context.Context) (string, error) {
+	raw, err := s.Get(ctx, hashKey)
+	if err == ErrNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+func recipientsHash(recipients []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(recipients, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// WatchRecipients streams recipient-list changes for this mount so
+// callers can invalidate any in-memory recipient cache as soon as another
+// node writes an update, without waiting for a `gopass sync`.
+func (s *Store) WatchRecipients(ctx context.Context) (<-chan Event, error) {
+	return s.Watch(ctx, recipientsKey)
+}
+
+func splitNonEmpty(s string) []string {
+	var out []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gopasspw/gopass/pkg/store/etcd"
+	"github.com/pkg/errors"
+)
+
+// etcdMountsMu guards etcdMounts. Mounts are process-wide rather than
+// per-Store because a single gopass process only ever has one root
+// Store. They are keyed by alias: two aliases that share a URL each get
+// their own connection.
+var (
+	etcdMountsMu sync.Mutex
+	etcdMounts   = map[string]*etcd.Store{}
+)
+
+// MountEtcd dials the etcd cluster described by rawurl (an alias's
+// `etcd://host[,host...]/prefix` mount-point URL) and registers the
+// resulting Store for alias, so a later EtcdStore(alias) call - and
+// therefore RecipientsAdd, RecipientsRemove and RecipientsUpdate - read
+// and write the shared store instead of only the local .gpg-id. tlsCfg
+// carries the --etcd-cert/--etcd-key/--etcd-ca flag values, shared by
+// every `etcd://` mount in this process.
+//
+// If invalidate is non-nil, it is called with alias whenever another
+// node changes the mount's recipient list, so a long-running caller can
+// drop its cached copy instead of waiting for a `gopass sync`.
+func MountEtcd(ctx context.Context, alias, rawurl string, tlsCfg etcd.Config, invalidate func(string)) error {
+	cfg, err := etcdConfigFromURL(rawurl, tlsCfg)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse etcd mount URL for %q", alias)
+	}
+
+	es, err := etcd.New(ctx, cfg)
+	if err != nil {
+		return errors.Wrapf(err, "failed to mount etcd store for %q", alias)
+	}
+
+	etcdMountsMu.Lock()
+	etcdMounts[alias] = es
+	etcdMountsMu.Unlock()
+
+	if invalidate != nil {
+		ch, err := es.WatchRecipients(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to watch etcd recipients for %q", alias)
+		}
+		go func() {
+			for range ch {
+				invalidate(alias)
+			}
+		}()
+	}
+
+	return nil
+}
+
+// etcdConfigFromURL turns an `etcd://host1,host2/prefix` mount URL plus
+// the shared TLS flag values into a ready-to-dial etcd.Config.
+func etcdConfigFromURL(rawurl string, tlsCfg etcd.Config) (etcd.Config, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return etcd.Config{}, errors.Wrapf(err, "invalid etcd mount URL %q", rawurl)
+	}
+	if u.Host == "" {
+		return etcd.Config{}, errors.Errorf("etcd mount URL %q has no host", rawurl)
+	}
+
+	cfg := tlsCfg
+	cfg.Endpoints = strings.Split(u.Host, ",")
+	cfg.Prefix = strings.Trim(u.Path, "/")
+	return cfg, nil
+}
+
+// EtcdStore returns the etcd-backed store mounted for alias, if its
+// config resolves to an `etcd://` URL and MountEtcd has dialed it.
+// RecipientsAdd, RecipientsRemove and RecipientsUpdate use this to read
+// and write the shared recipient list instead of, or in addition to, the
+// local .gpg-id.
+func (s *Store) EtcdStore(alias string) (*etcd.Store, bool) {
+	etcdMountsMu.Lock()
+	defer etcdMountsMu.Unlock()
+	es, ok := etcdMounts[alias]
+	return es, ok
+}
+
+// InvalidateRecipients drops any cached recipient list the sub-store for
+// alias may be holding, so the next read reflects whatever an etcd watch
+// just observed instead of a stale local cache.
+func (s *Store) InvalidateRecipients(alias string) {
+	sub, err := s.GetSubStore(alias)
+	if err != nil || sub == nil {
+		return
+	}
+	sub.InvalidateRecipients()
+}
@@ -0,0 +1,110 @@
+// Package group implements recipient groups: named, maintained sets of
+// fingerprints that a sub-store's .gpg-id can reference as `@groupname`
+// instead of listing every member individually.
+package group
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// File is the name of the per-sub-store file groups are persisted to,
+// sitting next to .gpg-id.
+const File = "groups.yml"
+
+// Prefix marks a recipient entry as a group reference rather than a
+// fingerprint, e.g. `@team-ops`.
+const Prefix = "@"
+
+// Groups maps a group name (without the leading Prefix) to its member
+// fingerprints.
+type Groups map[string][]string
+
+// Load reads the groups file at path. A missing file is treated as an
+// empty, valid set of groups.
+func Load(path string) (Groups, error) {
+	buf, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Groups{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read groups file %q", path)
+	}
+
+	g := Groups{}
+	if err := yaml.Unmarshal(buf, &g); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse groups file %q", path)
+	}
+	return g, nil
+}
+
+// Save writes g to path.
+func (g Groups) Save(path string) error {
+	buf, err := yaml.Marshal(g)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode groups file")
+	}
+	return ioutil.WriteFile(path, buf, 0600)
+}
+
+// IsGroup reports whether recipient is a group reference (`@name`) as
+// opposed to a plain fingerprint.
+func IsGroup(recipient string) bool {
+	return len(recipient) > 0 && recipient[:1] == Prefix
+}
+
+// Name strips the leading Prefix off a group reference.
+func Name(recipient string) string {
+	if IsGroup(recipient) {
+		return recipient[len(Prefix):]
+	}
+	return recipient
+}
+
+// Resolve expands any `@group` entries in recipients into their member
+// fingerprints, leaving plain fingerprints untouched. The result is
+// deduplicated and sorted, so the effective recipient list (and its
+// checksum) is stable regardless of group member ordering.
+func (g Groups) Resolve(recipients []string) []string {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(recipients))
+
+	add := func(fp string) {
+		if _, ok := seen[fp]; ok {
+			return
+		}
+		seen[fp] = struct{}{}
+		out = append(out, fp)
+	}
+
+	for _, r := range recipients {
+		if !IsGroup(r) {
+			add(r)
+			continue
+		}
+		for _, fp := range g[Name(r)] {
+			add(fp)
+		}
+	}
+
+	sort.Strings(out)
+	return out
+}
+
+// Checksum returns a stable checksum of recipients (expected to already
+// be deduplicated and sorted, e.g. via Resolve). RecipientsUpdate hashes
+// the group-expanded recipient set with this instead of the raw .gpg-id
+// content, so tampering with a group's membership in groups.yml still
+// triggers reconfirmation even though the .gpg-id entry (`@groupname`)
+// itself didn't change.
+func Checksum(recipients []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(recipients, "\n")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package group
+
+import "testing"
+
+func TestResolveExpandsAndDedupes(t *testing.T) {
+	g := Groups{
+		"ops": {"BBBB", "AAAA"},
+		"dev": {"AAAA", "CCCC"},
+	}
+
+	got := g.Resolve([]string{"@ops", "@dev", "DDDD"})
+	want := []string{"AAAA", "BBBB", "CCCC", "DDDD"}
+
+	if len(got) != len(want) {
+		t.Fatalf("Resolve() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Resolve() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestResolveUnknownGroupExpandsEmpty(t *testing.T) {
+	g := Groups{}
+	got := g.Resolve([]string{"@missing", "AAAA"})
+	want := []string{"AAAA"}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Fatalf("Resolve() = %v, want %v", got, want)
+	}
+}
+
+func TestIsGroupAndName(t *testing.T) {
+	if !IsGroup("@ops") {
+		t.Errorf("IsGroup(%q) = false, want true", "@ops")
+	}
+	if IsGroup("AAAA1111") {
+		t.Errorf("IsGroup(%q) = true, want false", "AAAA1111")
+	}
+	if Name("@ops") != "ops" {
+		t.Errorf("Name(%q) = %q, want %q", "@ops", Name("@ops"), "ops")
+	}
+	if Name("AAAA1111") != "AAAA1111" {
+		t.Errorf("Name(%q) = %q, want unchanged", "AAAA1111", Name("AAAA1111"))
+	}
+}
+
+func TestChecksumStableAndSetSensitive(t *testing.T) {
+	a := Checksum([]string{"AAAA", "BBBB"})
+	b := Checksum([]string{"AAAA", "BBBB"})
+	if a != b {
+		t.Fatalf("Checksum is not deterministic: %q != %q", a, b)
+	}
+
+	c := Checksum([]string{"AAAA", "BBBB", "CCCC"})
+	if a == c {
+		t.Fatalf("Checksum did not change when the recipient set changed")
+	}
+}
+
+func TestChecksumReflectsExpandedGroup(t *testing.T) {
+	before := Groups{"ops": {"AAAA"}}
+	after := Groups{"ops": {"AAAA", "BBBB"}}
+
+	rawBefore := before.Resolve([]string{"@ops"})
+	rawAfter := after.Resolve([]string{"@ops"})
+
+	if Checksum(rawBefore) == Checksum(rawAfter) {
+		t.Fatalf("Checksum did not change when group membership changed, even though the raw .gpg-id entry (@ops) stayed the same")
+	}
+}
@@ -0,0 +1,44 @@
+package group
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// syncTimeout bounds how long a `recipients group sync` waits for the
+// membership endpoint to respond.
+const syncTimeout = 10 * time.Second
+
+// FetchMembers retrieves the fingerprint list for a group from an
+// HTTP(S) endpoint, so an organization can manage team membership
+// centrally instead of editing every checkout's groups.yml by hand. The
+// endpoint is expected to return a JSON array of fingerprint strings.
+func FetchMembers(ctx context.Context, url string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, syncTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to build request for %q", url)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch group membership from %q", url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("group membership endpoint %q returned %s", url, resp.Status)
+	}
+
+	var members []string
+	if err := json.NewDecoder(resp.Body).Decode(&members); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode group membership from %q", url)
+	}
+	return members, nil
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/gopasspw/gopass/pkg/store/group"
+	"github.com/pkg/errors"
+)
+
+// GroupsFile returns the path to alias's groups.yml, sitting next to its
+// .gpg-id. This is the single place that knows where a sub-store's
+// groups file lives; callers in pkg/action reuse it instead of rebuilding
+// the path themselves.
+func (s *Store) GroupsFile(alias string) (string, error) {
+	sub, err := s.GetSubStore(alias)
+	if err != nil || sub == nil {
+		return "", errors.Wrapf(err, "failed to get sub store %q", alias)
+	}
+	return filepath.Join(filepath.Dir(sub.Crypto().IDFile()), group.File), nil
+}
+
+// EffectiveRecipients resolves the raw .gpg-id recipients for alias,
+// expanding any `@groupname` entry to its member fingerprints via that
+// sub-store's groups.yml, so a `.gpg-id` listing `@team-ops` encrypts to
+// every current team member rather than to a literal "@team-ops" key.
+// ReencryptAll calls this to compute the recipient set secrets are
+// rewritten to; the same resolution belongs in AddRecipient and
+// RemoveRecipient wherever a secret is encrypted for the first time, but
+// those live outside this package.
+func (s *Store) EffectiveRecipients(ctx context.Context, alias string) ([]string, error) {
+	sub, err := s.GetSubStore(alias)
+	if err != nil || sub == nil {
+		return nil, errors.Wrapf(err, "failed to get sub store %q", alias)
+	}
+
+	raw, err := sub.GetRecipients(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	groupsPath, err := s.GroupsFile(alias)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := group.Load(groupsPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load groups for %q", alias)
+	}
+
+	return groups.Resolve(raw), nil
+}
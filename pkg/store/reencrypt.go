@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/out"
+	"github.com/gopasspw/gopass/pkg/pwgen"
+	"github.com/gopasspw/gopass/pkg/store/secret"
+	"github.com/pkg/errors"
+)
+
+// ReencryptOptions controls how ReencryptAll walks and re-writes a
+// sub-store's secrets.
+type ReencryptOptions struct {
+	// Rotate causes any secret not explicitly opted out (via a
+	// `rotate: false` YAML body marker) to have its password replaced
+	// with a freshly generated one, on top of the re-encryption to the
+	// new recipient set.
+	Rotate bool
+
+	// PreviousRecipients is the recipient set alias's secrets are
+	// currently (still) encrypted to, captured by the caller before
+	// whatever recipient change prompted this call - e.g. before
+	// RecipientsRemove drops a recipient from .gpg-id. A failed run rolls
+	// already-rewritten secrets back to this set rather than to the new
+	// one, so a partial failure leaves every secret encrypted to the same
+	// set it started with instead of a mix of old and new. If empty,
+	// rollback re-encrypts to the current (post-change) effective set,
+	// which is only correct for a standalone call that isn't following a
+	// recipient change.
+	PreviousRecipients []string
+}
+
+// rotateMarkerKey is the YAML key a secret can set to `false` to opt out
+// of password rotation, e.g. after a RecipientsRemove --rotate.
+const rotateMarkerKey = "rotate"
+
+// ReencryptAll decrypts every secret below alias with the current
+// recipient set and writes it back, so it is re-encrypted for whoever is
+// left after a RecipientsRemove. When opts.Rotate is set, any secret that
+// doesn't carry a `rotate: false` marker also gets a freshly generated
+// password.
+//
+// Writes are staged with git commits suppressed (ctxutil.WithGitCommit),
+// so the whole batch lands as a single commit at the end instead of one
+// per secret. If any secret fails to re-encrypt, every secret already
+// rewritten in this run is written back to its pre-rotation plaintext,
+// re-encrypted to opts.PreviousRecipients rather than the new set, so
+// nothing is committed and every secret below alias ends up encrypted to
+// the same recipient set it started with - not a mix of the old set (on
+// secrets this run never reached) and the new one (on secrets it already
+// rewrote before failing).
+func (s *Store) ReencryptAll(ctx context.Context, alias string, opts ReencryptOptions) error {
+	sub, err := s.GetSubStore(alias)
+	if err != nil || sub == nil {
+		return errors.Wrapf(err, "failed to get sub store %q", alias)
+	}
+
+	names, err := sub.List(ctx, "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to list secrets below %q", alias)
+	}
+
+	// Resolve any `@group` entry in alias's .gpg-id to its current member
+	// fingerprints, so a secret re-encrypted here lands on the actual
+	// team, not on a literal "@team-ops" key.
+	effective, err := s.EffectiveRecipients(ctx, alias)
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve effective recipients for %q", alias)
+	}
+	previous := opts.PreviousRecipients
+	if len(previous) == 0 {
+		previous = effective
+	}
+	forwardCtx := ctxutil.WithRecipients(ctx, effective)
+	rollbackCtx := ctxutil.WithRecipients(ctx, previous)
+
+	type rewrite struct {
+		name string
+		old  *secret.Secret
+		new  *secret.Secret
+	}
+	rewrites := make([]rewrite, 0, len(names))
+
+	for i, name := range names {
+		out.Print(ctx, "Re-encrypting %s (%d/%d)", name, i+1, len(names))
+
+		old, err := sub.Get(ctx, name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decrypt %q, aborting re-encryption", name)
+		}
+
+		nw := old.Clone()
+		if opts.Rotate && rotatable(nw) {
+			nw.SetPassword(pwgen.GeneratePassword(pwgen.DefaultLength, false))
+		}
+
+		rewrites = append(rewrites, rewrite{name: name, old: old, new: nw})
+	}
+
+	noCommitForwardCtx := ctxutil.WithGitCommit(forwardCtx, false)
+	noCommitRollbackCtx := ctxutil.WithGitCommit(rollbackCtx, false)
+
+	done := make([]rewrite, 0, len(rewrites))
+	for _, r := range rewrites {
+		if err := sub.Set(noCommitForwardCtx, r.name, r.new); err != nil {
+			out.Print(ctx, "failed to re-encrypt %q, rolling back %d already-rewritten secret(s)", r.name, len(done))
+			for _, d := range done {
+				if rerr := sub.Set(noCommitRollbackCtx, d.name, d.old); rerr != nil {
+					return errors.Wrapf(rerr, "failed to roll back %q after re-encryption of %q failed: %s", d.name, r.name, err)
+				}
+			}
+			return errors.Wrapf(err, "failed to re-encrypt %q, rolled back", r.name)
+		}
+		done = append(done, r)
+	}
+
+	if err := sub.GitCommit(ctx, "Re-encrypted store after recipient removal"); err != nil {
+		return errors.Wrapf(err, "failed to commit re-encrypted store")
+	}
+
+	out.Green(ctx, "Re-encrypted %d secrets below %s", len(rewrites), alias)
+	return nil
+}
+
+// rotatable reports whether sec is eligible for password rotation, i.e.
+// it does not carry an explicit `rotate: false` marker in its YAML body.
+func rotatable(sec *secret.Secret) bool {
+	v, found := sec.Get(rotateMarkerKey)
+	return rotateAllowed(v, found)
+}
+
+// rotateAllowed reports whether a secret with the given rotate-marker
+// value may be rotated, i.e. the value is not `false`. found is false when the secret has no `rotate` key at
+// all, in which case rotation is allowed.
+func rotateAllowed(value string, found bool) bool {
+	if !found {
+		return true
+	}
+	return !strings.EqualFold(strings.TrimSpace(value), "false")
+}
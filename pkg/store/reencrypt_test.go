@@ -0,0 +1,27 @@
+package store
+
+import "testing"
+
+func TestRotateAllowed(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		found bool
+		want  bool
+	}{
+		{name: "no marker", value: "", found: false, want: true},
+		{name: "marker false", value: "false", found: true, want: false},
+		{name: "marker false mixed case", value: "False", found: true, want: false},
+		{name: "marker false with whitespace", value: "  false\n", found: true, want: false},
+		{name: "marker true", value: "true", found: true, want: true},
+		{name: "marker unrelated value", value: "yes", found: true, want: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := rotateAllowed(tc.value, tc.found); got != tc.want {
+				t.Errorf("rotateAllowed(%q, %v) = %v, want %v", tc.value, tc.found, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package termio
+
+import (
+	"io/ioutil"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// BatchInput answers the confirmation and password prompts, and supplies
+// the fingerprints, that RecipientsAdd/Remove/Update would otherwise ask
+// for on a TTY, reading them from a pre-populated file instead. This
+// lets CI systems rotate recipients without a TTY attached. None of the
+// recipients commands currently prompt for a password themselves, but
+// Password is provided so BatchInput satisfies the same shape as
+// termio.AskForConfirmation/termio.AskForPassword for callers elsewhere
+// that do.
+//
+// prompts and passwords are keyed by a stable identifier (e.g.
+// "add:<fingerprint>"), not by the human-readable prompt text, so a
+// batch file keeps working even if a key's rendered name or comment
+// changes. Example:
+//
+//	store: teams/ops
+//	recipients:
+//	  AAAA1111BBBB2222: add
+//	  CCCC3333DDDD4444: remove
+//	prompts:
+//	  "add:AAAA1111BBBB2222": true
+//	  "remove-self:CCCC3333DDDD4444": true
+//	passwords:
+//	  "unlock:AAAA1111BBBB2222": "hunter2"
+type BatchInput struct {
+	Store      string            `yaml:"store"`
+	Recipients map[string]string `yaml:"recipients"`
+	Prompts    map[string]bool   `yaml:"prompts"`
+	Passwords  map[string]string `yaml:"passwords"`
+}
+
+// LoadBatchInput reads and parses the batch file at path.
+func LoadBatchInput(path string) (*BatchInput, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read batch file %q", path)
+	}
+
+	bi := &BatchInput{}
+	if err := yaml.Unmarshal(buf, bi); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse batch file %q", path)
+	}
+	return bi, nil
+}
+
+// Confirm looks up the answer recorded for the stable prompt key. found
+// is false if the batch file has no entry for it, so callers can fail
+// loudly instead of silently defaulting to "no".
+func (b *BatchInput) Confirm(key string) (answer bool, found bool) {
+	answer, found = b.Prompts[key]
+	return answer, found
+}
+
+// Password looks up the password recorded for the stable prompt key,
+// mirroring Confirm's found-semantics so a caller can fail loudly
+// instead of silently falling back to an empty password.
+func (b *BatchInput) Password(key string) (password string, found bool) {
+	password, found = b.Passwords[key]
+	return password, found
+}
+
+// FingerprintsFor returns the fingerprints recorded against action
+// ("add" or "remove"), sorted for deterministic iteration, so
+// RecipientsAdd/RecipientsRemove can drive their recipient loop straight
+// off the batch file when no fingerprints were given on the command line.
+func (b *BatchInput) FingerprintsFor(action string) []string {
+	var fps []string
+	for fp, a := range b.Recipients {
+		if a == action {
+			fps = append(fps, fp)
+		}
+	}
+	sort.Strings(fps)
+	return fps
+}
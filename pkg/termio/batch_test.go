@@ -0,0 +1,54 @@
+package termio
+
+import "testing"
+
+func TestBatchInputConfirm(t *testing.T) {
+	b := &BatchInput{Prompts: map[string]bool{"add:AAAA": true, "remove:BBBB": false}}
+
+	if answer, found := b.Confirm("add:AAAA"); !found || !answer {
+		t.Errorf("Confirm(add:AAAA) = (%v, %v), want (true, true)", answer, found)
+	}
+	if answer, found := b.Confirm("remove:BBBB"); !found || answer {
+		t.Errorf("Confirm(remove:BBBB) = (%v, %v), want (false, true)", answer, found)
+	}
+	if _, found := b.Confirm("trust:missing"); found {
+		t.Errorf("Confirm(trust:missing) found = true, want false")
+	}
+}
+
+func TestBatchInputPassword(t *testing.T) {
+	b := &BatchInput{Passwords: map[string]string{"unlock:AAAA": "hunter2"}}
+
+	if password, found := b.Password("unlock:AAAA"); !found || password != "hunter2" {
+		t.Errorf("Password(unlock:AAAA) = (%q, %v), want (%q, true)", password, found, "hunter2")
+	}
+	if _, found := b.Password("unlock:missing"); found {
+		t.Errorf("Password(unlock:missing) found = true, want false")
+	}
+}
+
+func TestBatchInputFingerprintsFor(t *testing.T) {
+	b := &BatchInput{Recipients: map[string]string{
+		"BBBB": "add",
+		"AAAA": "add",
+		"CCCC": "remove",
+	}}
+
+	got := b.FingerprintsFor("add")
+	want := []string{"AAAA", "BBBB"}
+	if len(got) != len(want) {
+		t.Fatalf("FingerprintsFor(add) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("FingerprintsFor(add) = %v, want %v (expected sorted order)", got, want)
+		}
+	}
+
+	if got := b.FingerprintsFor("remove"); len(got) != 1 || got[0] != "CCCC" {
+		t.Errorf("FingerprintsFor(remove) = %v, want [CCCC]", got)
+	}
+	if got := b.FingerprintsFor("unknown"); len(got) != 0 {
+		t.Errorf("FingerprintsFor(unknown) = %v, want empty", got)
+	}
+}